@@ -0,0 +1,255 @@
+package bblfsh
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	protocol2 "gopkg.in/bblfsh/sdk.v2/protocol"
+)
+
+// ParseInput is a single unit of work submitted to a BatchParseRequest.
+type ParseInput struct {
+	// Path is the filename to report on the response and, when Content
+	// is nil, the local path to read the source from.
+	Path string
+	// Content overrides reading Path from disk, when set.
+	Content []byte
+	// Language overrides language detection, when set.
+	Language string
+}
+
+// BatchResult is the outcome of parsing a single ParseInput.
+type BatchResult struct {
+	Path     string
+	Response *protocol2.ParseResponse
+	Err      error
+}
+
+// BatchStats holds aggregate counters updated while a BatchParseRequest
+// runs. All methods are safe to call concurrently with the batch in
+// progress.
+type BatchStats struct {
+	Files  int64
+	Bytes  int64
+	Errors int64
+
+	start time.Time
+
+	mu    sync.Mutex
+	langs map[string]int64
+}
+
+func newBatchStats() *BatchStats {
+	return &BatchStats{start: time.Now(), langs: make(map[string]int64)}
+}
+
+func (s *BatchStats) record(bytes int, lang string, err error) {
+	atomic.AddInt64(&s.Files, 1)
+	atomic.AddInt64(&s.Bytes, int64(bytes))
+	if err != nil {
+		atomic.AddInt64(&s.Errors, 1)
+	}
+	if lang == "" {
+		return
+	}
+	s.mu.Lock()
+	s.langs[lang]++
+	s.mu.Unlock()
+}
+
+// LanguageCounts returns a snapshot of the number of files parsed per
+// detected language.
+func (s *BatchStats) LanguageCounts() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]int64, len(s.langs))
+	for k, v := range s.langs {
+		out[k] = v
+	}
+	return out
+}
+
+// FilesPerSecond returns the current throughput, measured from the moment
+// the BatchParseRequest was created.
+func (s *BatchStats) FilesPerSecond() float64 {
+	elapsed := time.Since(s.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&s.Files)) / elapsed
+}
+
+// BatchParseRequest parses a set of files concurrently, using a bounded
+// pool of workers that share the underlying Client connection.
+type BatchParseRequest struct {
+	client  *Client
+	workers int
+	timeout time.Duration
+
+	paths []string
+	root  string
+	in    <-chan ParseInput
+
+	stats *BatchStats
+	err   error
+
+	// parse performs a single ParseInput. It defaults to parseOne and is
+	// only overridden in tests, to exercise Do's worker pool and
+	// cancellation handling without a real bblfshd connection.
+	parse func(ctx context.Context, in ParseInput) BatchResult
+}
+
+// NewBatchParseRequest creates a request to parse many files, streaming
+// the results back over a channel instead of collecting them in memory.
+func (c *Client) NewBatchParseRequest() *BatchParseRequest {
+	r := &BatchParseRequest{client: c, workers: 4, stats: newBatchStats()}
+	r.parse = r.parseOne
+	return r
+}
+
+// Paths adds a fixed list of local files to parse.
+func (r *BatchParseRequest) Paths(paths ...string) *BatchParseRequest {
+	r.paths = append(r.paths, paths...)
+	return r
+}
+
+// Root walks the given directory and parses every regular file found
+// under it.
+func (r *BatchParseRequest) Root(dir string) *BatchParseRequest {
+	r.root = dir
+	return r
+}
+
+// Inputs sets an explicit channel of work items, taking precedence over
+// Paths and Root.
+func (r *BatchParseRequest) Inputs(in <-chan ParseInput) *BatchParseRequest {
+	r.in = in
+	return r
+}
+
+// Workers sets the size of the worker pool. The default is 4.
+func (r *BatchParseRequest) Workers(n int) *BatchParseRequest {
+	if n > 0 {
+		r.workers = n
+	}
+	return r
+}
+
+// Timeout sets a per-file parse timeout, applied on top of the context
+// passed to Do.
+func (r *BatchParseRequest) Timeout(d time.Duration) *BatchParseRequest {
+	r.timeout = d
+	return r
+}
+
+// Stats returns the aggregate statistics for this batch. It can be read
+// while the batch is in progress.
+func (r *BatchParseRequest) Stats() *BatchStats {
+	return r.stats
+}
+
+// Do starts the batch and streams results back on the returned channel.
+// The channel is closed once every input has been processed or ctx is
+// canceled.
+func (r *BatchParseRequest) Do(ctx context.Context) (<-chan BatchResult, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	in := r.in
+	if in == nil {
+		in = r.walk(ctx)
+	}
+
+	out := make(chan BatchResult)
+	var wg sync.WaitGroup
+	wg.Add(r.workers)
+	for i := 0; i < r.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case input, ok := <-in:
+					if !ok {
+						return
+					}
+					select {
+					case out <- r.parse(ctx, input):
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out, nil
+}
+
+// walk produces a channel of ParseInput from r.root and r.paths.
+func (r *BatchParseRequest) walk(ctx context.Context) <-chan ParseInput {
+	ch := make(chan ParseInput)
+	go func() {
+		defer close(ch)
+		if r.root != "" {
+			_ = filepath.Walk(r.root, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				select {
+				case ch <- ParseInput{Path: path}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				return nil
+			})
+		}
+		for _, p := range r.paths {
+			select {
+			case ch <- ParseInput{Path: p}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func (r *BatchParseRequest) parseOne(ctx context.Context, in ParseInput) BatchResult {
+	req := &ParseRequestV2{client: r.client}
+	if in.Content != nil {
+		req.Content(string(in.Content)).Filename(in.Path)
+	} else {
+		req.ReadFile(in.Path)
+	}
+	if in.Language != "" {
+		req.Language(in.Language)
+	}
+
+	reqCtx := ctx
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	resp, err := req.DoContext(reqCtx)
+	r.stats.record(len(req.internal.Content), langOf(resp), err)
+	return BatchResult{Path: in.Path, Response: resp, Err: err}
+}
+
+func langOf(resp *protocol2.ParseResponse) string {
+	if resp == nil {
+		return ""
+	}
+	return resp.Language
+}
@@ -0,0 +1,155 @@
+package bblfsh
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchStatsRecord(t *testing.T) {
+	s := newBatchStats()
+	s.record(100, "Go", nil)
+	s.record(50, "Go", nil)
+	s.record(10, "Python", errTest)
+
+	if s.Files != 3 {
+		t.Fatalf("Files = %d, want 3", s.Files)
+	}
+	if s.Bytes != 160 {
+		t.Fatalf("Bytes = %d, want 160", s.Bytes)
+	}
+	if s.Errors != 1 {
+		t.Fatalf("Errors = %d, want 1", s.Errors)
+	}
+
+	counts := s.LanguageCounts()
+	if counts["Go"] != 2 {
+		t.Errorf("LanguageCounts[Go] = %d, want 2", counts["Go"])
+	}
+	if counts["Python"] != 1 {
+		t.Errorf("LanguageCounts[Python] = %d, want 1", counts["Python"])
+	}
+}
+
+func TestBatchStatsRecordEmptyLanguage(t *testing.T) {
+	s := newBatchStats()
+	s.record(1, "", nil)
+
+	if len(s.LanguageCounts()) != 0 {
+		t.Fatalf("expected no language counts to be recorded for an empty language")
+	}
+}
+
+func TestBatchParseRequestDoStreamsEveryInput(t *testing.T) {
+	var calls int64
+	r := &BatchParseRequest{
+		workers: 3,
+		stats:   newBatchStats(),
+		paths:   []string{"a.go", "b.go", "c.go"},
+	}
+	r.parse = func(ctx context.Context, in ParseInput) BatchResult {
+		atomic.AddInt64(&calls, 1)
+		return BatchResult{Path: in.Path}
+	}
+
+	out, err := r.Do(context.Background())
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for res := range out {
+		seen[res.Path] = true
+	}
+	if len(seen) != len(r.paths) {
+		t.Fatalf("got %d distinct results, want %d", len(seen), len(r.paths))
+	}
+	for _, p := range r.paths {
+		if !seen[p] {
+			t.Errorf("missing a result for %q", p)
+		}
+	}
+	if calls != int64(len(r.paths)) {
+		t.Errorf("parse was called %d times, want %d", calls, len(r.paths))
+	}
+}
+
+// TestBatchParseRequestDoStopsOnContextCancellation asserts that Do does
+// not deadlock when ctx is already canceled: workers must still drain
+// and return instead of blocking forever trying to send a result nobody
+// will read.
+func TestBatchParseRequestDoStopsOnContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	in := make(chan ParseInput, 3)
+	in <- ParseInput{Path: "a.go"}
+	in <- ParseInput{Path: "b.go"}
+	in <- ParseInput{Path: "c.go"}
+	close(in)
+
+	r := &BatchParseRequest{
+		workers: 1,
+		stats:   newBatchStats(),
+		in:      in,
+	}
+	r.parse = func(ctx context.Context, in ParseInput) BatchResult {
+		return BatchResult{Path: in.Path}
+	}
+
+	out, err := r.Do(ctx)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Do did not stop after the context was canceled")
+		}
+	}
+}
+
+func TestWalkProducesInputsFromRootAndPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package main"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	r := &BatchParseRequest{root: dir, paths: []string{"extra.go"}}
+
+	var got []string
+	for in := range r.walk(context.Background()) {
+		got = append(got, in.Path)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("walk produced %d inputs, want 3: %v", len(got), got)
+	}
+	want := map[string]bool{
+		filepath.Join(dir, "a.go"): true,
+		filepath.Join(dir, "b.go"): true,
+		"extra.go":                 true,
+	}
+	for _, p := range got {
+		if !want[p] {
+			t.Errorf("unexpected path %q", p)
+		}
+	}
+}
+
+var errTest = &testError{"boom"}
+
+type testError struct{ msg string }
+
+func (e *testError) Error() string { return e.msg }
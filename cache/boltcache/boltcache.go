@@ -0,0 +1,69 @@
+// Package boltcache implements a bblfsh/cache.Cache backed by a single
+// BoltDB file, useful when the number of cached UASTs is too large to
+// keep as loose files.
+package boltcache
+
+import (
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bblfsh/client-go/cache"
+)
+
+var bucketName = []byte("uast")
+
+// Cache is a cache.Cache backed by a BoltDB database.
+type Cache struct {
+	db *bolt.DB
+}
+
+// Open opens, creating it if necessary, a BoltDB-backed cache at path.
+func Open(path string) (*Cache, error) {
+	db, err := bolt.Open(path, 0644, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Cache{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// Get implements cache.Cache.
+func (c *Cache) Get(key cache.Key) (cache.Value, bool, error) {
+	var data []byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(bucketName).Get(key[:]); v != nil {
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil || data == nil {
+		return cache.Value{}, false, err
+	}
+	v, err := cache.DecodeValue(data)
+	if err != nil {
+		return cache.Value{}, false, err
+	}
+	return v, true, nil
+}
+
+// Put implements cache.Cache.
+func (c *Cache) Put(key cache.Key, v cache.Value) error {
+	data, err := cache.EncodeValue(v)
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put(key[:], data)
+	})
+}
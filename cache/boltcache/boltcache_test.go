@@ -0,0 +1,55 @@
+package boltcache
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/bblfsh/sdk.v2/uast/nodes"
+
+	"github.com/bblfsh/client-go/cache"
+)
+
+func TestCachePutGet(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), "uast.bolt"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer c.Close()
+
+	key := cache.Key{1, 2, 3}
+	v := cache.Value{Language: "Go", AST: nodes.String("x")}
+
+	if err := c.Put(key, v); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, ok, err := c.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected a cache hit after Put")
+	}
+	if got.Language != v.Language {
+		t.Errorf("Language = %q, want %q", got.Language, v.Language)
+	}
+	if s, ok := got.AST.(nodes.String); !ok || s != "x" {
+		t.Errorf("AST = %#v, want %#v", got.AST, v.AST)
+	}
+}
+
+func TestCacheGetMiss(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), "uast.bolt"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer c.Close()
+
+	_, ok, err := c.Get(cache.Key{9, 9, 9})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if ok {
+		t.Fatal("expected a cache miss for a key that was never Put")
+	}
+}
@@ -0,0 +1,71 @@
+// Package cache defines the pluggable UAST cache used by the client to
+// avoid re-parsing files it has already seen.
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"gopkg.in/bblfsh/sdk.v2/uast/nodes"
+	"gopkg.in/bblfsh/sdk.v2/uast/nodes/nodesproto"
+)
+
+// Key identifies a cached UAST. Callers derive it from the SHA-256 digest
+// of the (language, mode, content) triple of the request it was parsed
+// from.
+type Key [32]byte
+
+// Value is a cached parse result: the language bblfshd detected alongside
+// the UAST it returned for it. The language must be cached too, since a
+// request commonly leaves it unset and relies on auto-detection.
+type Value struct {
+	Language string
+	AST      nodes.Node
+}
+
+// Cache is implemented by UAST storage backends. ParseRequestV2.UASTContext
+// and ParseRequest.UASTContext consult it before sending a parse request
+// to bblfshd, and store the result on a miss.
+type Cache interface {
+	// Get returns the Value stored under key, or ok == false on a miss.
+	Get(key Key) (v Value, ok bool, err error)
+	// Put stores v under key.
+	Put(key Key, v Value) error
+}
+
+// EncodeValue serializes v as a length-prefixed language string followed
+// by the UAST in the SDK's native binary encoding. Cache implementations
+// that store raw bytes (fscache, boltcache) use it to build their stored
+// value.
+func EncodeValue(v Value) ([]byte, error) {
+	var buf bytes.Buffer
+	lang := []byte(v.Language)
+	if err := binary.Write(&buf, binary.BigEndian, uint16(len(lang))); err != nil {
+		return nil, err
+	}
+	buf.Write(lang)
+	if err := nodesproto.WriteTo(&buf, v.AST); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeValue reverses EncodeValue.
+func DecodeValue(data []byte) (Value, error) {
+	r := bytes.NewReader(data)
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return Value{}, fmt.Errorf("cannot decode cache entry: %v", err)
+	}
+	lang := make([]byte, n)
+	if _, err := io.ReadFull(r, lang); err != nil {
+		return Value{}, fmt.Errorf("cannot decode cache entry: %v", err)
+	}
+	ast, err := nodesproto.ReadTree(r)
+	if err != nil {
+		return Value{}, fmt.Errorf("cannot decode cache entry: %v", err)
+	}
+	return Value{Language: string(lang), AST: ast}, nil
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"testing"
+
+	"gopkg.in/bblfsh/sdk.v2/uast/nodes"
+)
+
+func TestEncodeDecodeValueRoundTrip(t *testing.T) {
+	v := Value{
+		Language: "Go",
+		AST: nodes.Object{
+			"key": nodes.String("value"),
+		},
+	}
+
+	data, err := EncodeValue(v)
+	if err != nil {
+		t.Fatalf("EncodeValue: %v", err)
+	}
+
+	got, err := DecodeValue(data)
+	if err != nil {
+		t.Fatalf("DecodeValue: %v", err)
+	}
+	if got.Language != v.Language {
+		t.Errorf("Language = %q, want %q", got.Language, v.Language)
+	}
+	if s, ok := got.AST.(nodes.Object)["key"].(nodes.String); !ok || string(s) != "value" {
+		t.Errorf("AST was not preserved by the round trip: %#v", got.AST)
+	}
+}
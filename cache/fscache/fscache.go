@@ -0,0 +1,54 @@
+// Package fscache implements a bblfsh/cache.Cache backed by a directory
+// of files on the local filesystem, one per cached UAST.
+package fscache
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/bblfsh/client-go/cache"
+)
+
+// Cache is a cache.Cache that stores each entry as a single file, named
+// after the hex-encoded cache key, inside a directory.
+type Cache struct {
+	dir string
+}
+
+// New creates a filesystem cache rooted at dir, creating the directory if
+// it does not already exist.
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir}, nil
+}
+
+func (c *Cache) path(key cache.Key) string {
+	return filepath.Join(c.dir, hex.EncodeToString(key[:]))
+}
+
+// Get implements cache.Cache.
+func (c *Cache) Get(key cache.Key) (cache.Value, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return cache.Value{}, false, nil
+	} else if err != nil {
+		return cache.Value{}, false, err
+	}
+	v, err := cache.DecodeValue(data)
+	if err != nil {
+		return cache.Value{}, false, err
+	}
+	return v, true, nil
+}
+
+// Put implements cache.Cache.
+func (c *Cache) Put(key cache.Key, v cache.Value) error {
+	data, err := cache.EncodeValue(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(key), data, 0644)
+}
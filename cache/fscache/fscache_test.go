@@ -0,0 +1,52 @@
+package fscache
+
+import (
+	"testing"
+
+	"gopkg.in/bblfsh/sdk.v2/uast/nodes"
+
+	"github.com/bblfsh/client-go/cache"
+)
+
+func TestCachePutGet(t *testing.T) {
+	c, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	key := cache.Key{1, 2, 3}
+	v := cache.Value{Language: "Go", AST: nodes.String("x")}
+
+	if err := c.Put(key, v); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, ok, err := c.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected a cache hit after Put")
+	}
+	if got.Language != v.Language {
+		t.Errorf("Language = %q, want %q", got.Language, v.Language)
+	}
+	if s, ok := got.AST.(nodes.String); !ok || s != "x" {
+		t.Errorf("AST = %#v, want %#v", got.AST, v.AST)
+	}
+}
+
+func TestCacheGetMiss(t *testing.T) {
+	c, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, ok, err := c.Get(cache.Key{9, 9, 9})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if ok {
+		t.Fatal("expected a cache miss for a key that was never Put")
+	}
+}
@@ -0,0 +1,20 @@
+package bblfsh
+
+import (
+	"crypto/sha256"
+
+	"github.com/bblfsh/client-go/cache"
+)
+
+// cacheKey derives the cache key for a parse request as the SHA-256
+// digest of its (language, mode, content) triple.
+func cacheKey(language string, mode Mode, content string) cache.Key {
+	h := sha256.New()
+	h.Write([]byte(language))
+	h.Write([]byte{byte(mode)})
+	h.Write([]byte(content))
+
+	var key cache.Key
+	copy(key[:], h.Sum(nil))
+	return key
+}
@@ -0,0 +1,28 @@
+package bblfsh
+
+import "testing"
+
+func TestCacheKeyIsStableAndDistinct(t *testing.T) {
+	a := cacheKey("Go", Native, "package main")
+	b := cacheKey("Go", Native, "package main")
+	if a != b {
+		t.Fatalf("cacheKey is not deterministic: %x != %x", a, b)
+	}
+
+	cases := []cacheKeyCase{
+		{"Python", Native, "package main"},
+		{"Go", Semantic, "package main"},
+		{"Go", Native, "package other"},
+	}
+	for _, c := range cases {
+		if got := cacheKey(c.lang, c.mode, c.content); got == a {
+			t.Errorf("cacheKey(%q, %v, %q) collided with the baseline key", c.lang, c.mode, c.content)
+		}
+	}
+}
+
+type cacheKeyCase struct {
+	lang    string
+	mode    Mode
+	content string
+}
@@ -0,0 +1,104 @@
+package bblfsh
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+
+	"github.com/bblfsh/client-go/cache"
+	"github.com/bblfsh/client-go/observability"
+	protocol1 "gopkg.in/bblfsh/sdk.v1/protocol"
+	protocol2 "gopkg.in/bblfsh/sdk.v2/protocol"
+)
+
+// Client holds the connections to a bblfshd instance and is safe for
+// concurrent use by multiple goroutines.
+type Client struct {
+	pool     *connPool
+	poolSize int
+	dialOpts []grpc.DialOption
+
+	retry *RetryPolicy
+	cache cache.Cache
+
+	metrics *observability.Metrics
+	tracer  opentracing.Tracer
+}
+
+// NewClient returns a Client connected to the given bblfshd endpoint.
+func NewClient(endpoint string, opts ...Option) (*Client, error) {
+	return NewClientContext(context.Background(), endpoint, opts...)
+}
+
+// NewClientContext is the same as NewClient, but supports cancellation by
+// the use of Go contexts.
+func NewClientContext(ctx context.Context, endpoint string, opts ...Option) (*Client, error) {
+	c := &Client{poolSize: 1}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	n := c.poolSize
+	if n < 1 {
+		n = 1
+	}
+	dialOpts := append([]grpc.DialOption{grpc.WithInsecure()}, c.dialOpts...)
+
+	conns := make([]*grpc.ClientConn, n)
+	for i := range conns {
+		conn, err := grpc.DialContext(ctx, endpoint, dialOpts...)
+		if err != nil {
+			return nil, err
+		}
+		conns[i] = conn
+	}
+	c.pool = newConnPool(conns)
+
+	return c, nil
+}
+
+// Close closes every connection held by the client.
+func (c *Client) Close() error {
+	return c.pool.Close()
+}
+
+// service1Client returns the protocol v1 client to use for the next
+// request, round-robining across the connection pool.
+func (c *Client) service1Client() protocol1.ProtocolServiceClient {
+	return protocol1.NewProtocolServiceClient(c.pool.Get())
+}
+
+// service2Client returns the protocol v2 client to use for the next
+// request, round-robining across the connection pool.
+func (c *Client) service2Client() protocol2.ProtocolServiceClient {
+	return protocol2.NewProtocolServiceClient(c.pool.Get())
+}
+
+// NewParseRequestV2 creates a new parsing request to get the UAST.
+func (c *Client) NewParseRequestV2() *ParseRequestV2 {
+	return &ParseRequestV2{client: c}
+}
+
+// NewParseRequest creates a new parsing request to get the UAST using the
+// legacy v1 protocol, optionally downgraded from a v2 response.
+func (c *Client) NewParseRequest() *ParseRequest {
+	return &ParseRequest{client: c}
+}
+
+// NewNativeParseRequest creates a new parsing request to get the native
+// AST.
+func (c *Client) NewNativeParseRequest() *NativeParseRequest {
+	return &NativeParseRequest{client: c}
+}
+
+// NewVersionRequest creates a new request to retrieve the server version.
+func (c *Client) NewVersionRequest() *VersionRequest {
+	return &VersionRequest{client: c}
+}
+
+// NewSupportedLanguagesRequest creates a new request to retrieve the
+// supported languages.
+func (c *Client) NewSupportedLanguagesRequest() *SupportedLanguagesRequest {
+	return &SupportedLanguagesRequest{client: c}
+}
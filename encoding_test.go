@@ -0,0 +1,100 @@
+package bblfsh
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+
+	protocol1 "gopkg.in/bblfsh/sdk.v1/protocol"
+)
+
+func TestSniffUTF16(t *testing.T) {
+	le, _ := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewEncoder().String("hi")
+	be, _ := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewEncoder().String("hi")
+
+	if sniffUTF16(le) == nil {
+		t.Error("expected a UTF-16LE BOM to be detected")
+	}
+	if sniffUTF16(be) == nil {
+		t.Error("expected a UTF-16BE BOM to be detected")
+	}
+	if sniffUTF16("plain ascii") != nil {
+		t.Error("expected no charset to be detected for plain ASCII")
+	}
+}
+
+func TestTranscodeToUTF8Charset(t *testing.T) {
+	encoded, err := charmap.Windows1252.NewEncoder().String("café")
+	if err != nil {
+		t.Fatalf("failed to set up the test fixture: %v", err)
+	}
+
+	r := &ParseRequestV2{}
+	r.Content(encoded).Charset(charmap.Windows1252)
+
+	if err := r.transcodeToUTF8(); err != nil {
+		t.Fatalf("transcodeToUTF8: %v", err)
+	}
+	if r.internal.Content != "café" {
+		t.Errorf("Content = %q, want %q", r.internal.Content, "café")
+	}
+	if r.detectedEncoding == "utf-8" {
+		t.Errorf("DetectedEncoding should not report utf-8 when a charset was transcoded")
+	}
+}
+
+func TestTranscodeToUTF8Base64(t *testing.T) {
+	r := &ParseRequestV2{}
+	r.Content(base64.StdEncoding.EncodeToString([]byte("package main"))).Encoding(protocol1.Base64)
+
+	if err := r.transcodeToUTF8(); err != nil {
+		t.Fatalf("transcodeToUTF8: %v", err)
+	}
+	if r.internal.Content != "package main" {
+		t.Errorf("Content = %q, want %q", r.internal.Content, "package main")
+	}
+}
+
+func TestTranscodeToUTF8RetriesAfterAFailedAttempt(t *testing.T) {
+	r := &ParseRequestV2{}
+	r.Content("not valid base64!!").Encoding(protocol1.Base64)
+
+	if err := r.transcodeToUTF8(); err == nil {
+		t.Fatal("expected a decode error for invalid base64 content")
+	}
+	if r.transcoded {
+		t.Fatal("a failed transcode must not be marked as transcoded")
+	}
+
+	r.internal.Content = base64.StdEncoding.EncodeToString([]byte("package main"))
+	if err := r.transcodeToUTF8(); err != nil {
+		t.Fatalf("transcodeToUTF8: %v", err)
+	}
+	if r.internal.Content != "package main" {
+		t.Errorf("Content = %q, want %q", r.internal.Content, "package main")
+	}
+}
+
+func TestTranscodeToUTF8NoOpAndIdempotent(t *testing.T) {
+	r := &ParseRequestV2{}
+	r.Content("package main")
+
+	if err := r.transcodeToUTF8(); err != nil {
+		t.Fatalf("transcodeToUTF8: %v", err)
+	}
+	if r.detectedEncoding != "utf-8" {
+		t.Errorf("DetectedEncoding = %q, want %q", r.detectedEncoding, "utf-8")
+	}
+
+	// A second call must be a no-op, even if the (already-transcoded)
+	// content would otherwise be misread as something else.
+	r.internal.Content = "sentinel"
+	if err := r.transcodeToUTF8(); err != nil {
+		t.Fatalf("transcodeToUTF8: %v", err)
+	}
+	if r.internal.Content != "sentinel" {
+		t.Errorf("a second call to transcodeToUTF8 should be a no-op")
+	}
+}
@@ -0,0 +1,74 @@
+package bblfsh
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bblfsh/client-go/observability"
+	"gopkg.in/bblfsh/sdk.v2/uast/nodes"
+)
+
+// WithMetrics registers Prometheus metrics for every request made through
+// the client on reg.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(cl *Client) {
+		cl.metrics = observability.Register(reg)
+	}
+}
+
+// WithTracer opens an OpenTracing span, tagged with the request's
+// language, filename and content size, around every request made through
+// the client.
+func WithTracer(tracer opentracing.Tracer) Option {
+	return func(cl *Client) {
+		cl.tracer = tracer
+	}
+}
+
+// instrument opens a span and records metrics around fn, which should
+// perform a single request and report what it learned about the response
+// once it completes. requestLanguage is used only for the span, and as
+// the metrics label when fn can't report a more specific one (e.g. the
+// request failed before bblfshd could detect it).
+func (c *Client) instrument(ctx context.Context, method, requestLanguage, filename string, contentBytes int, fn func() (observability.Outcome, error)) error {
+	span, _ := observability.StartSpan(ctx, c.tracer, method, requestLanguage, filename, contentBytes)
+	defer span.Finish()
+
+	start := time.Now()
+	out, err := fn()
+	if out.Language == "" {
+		out.Language = requestLanguage
+	}
+	c.metrics.Observe(method, time.Since(start), out, err)
+	if err != nil {
+		span.SetTag("error", true)
+	}
+	span.SetTag("language", out.Language)
+	return err
+}
+
+// countNodes returns the number of nodes in ast, used to report response
+// size on the node-count metric.
+func countNodes(ast nodes.Node) int {
+	switch n := ast.(type) {
+	case nodes.Object:
+		count := 1
+		for _, v := range n {
+			count += countNodes(v)
+		}
+		return count
+	case nodes.Array:
+		count := 1
+		for _, v := range n {
+			count += countNodes(v)
+		}
+		return count
+	case nil:
+		return 0
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,33 @@
+package bblfsh
+
+import (
+	"testing"
+
+	"gopkg.in/bblfsh/sdk.v2/uast/nodes"
+)
+
+func TestCountNodes(t *testing.T) {
+	cases := []struct {
+		name string
+		ast  nodes.Node
+		want int
+	}{
+		{"nil", nil, 0},
+		{"leaf", nodes.String("x"), 1},
+		{"array", nodes.Array{nodes.String("a"), nodes.String("b")}, 3},
+		{"object", nodes.Object{"a": nodes.String("x"), "b": nodes.String("y")}, 3},
+		{"nested", nodes.Object{
+			"children": nodes.Array{
+				nodes.Object{"name": nodes.String("x")},
+				nodes.Object{"name": nodes.String("y")},
+			},
+		}, 6},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := countNodes(c.ast); got != c.want {
+				t.Errorf("countNodes(%#v) = %d, want %d", c.ast, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package observability provides the Prometheus metrics and OpenTracing
+// spans used to instrument bblfsh client requests.
+package observability
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus collectors shared by every instrumented
+// request.
+type Metrics struct {
+	Latency       *prometheus.HistogramVec
+	ResponseNodes *prometheus.HistogramVec
+	ResponseBytes *prometheus.HistogramVec
+	Requests      *prometheus.CounterVec
+}
+
+// Register creates the client's metrics and registers them on reg.
+func Register(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		Latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "bblfsh_client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of bblfsh client requests.",
+		}, []string{"method", "language"}),
+		ResponseNodes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "bblfsh_client",
+			Name:      "response_nodes",
+			Help:      "Number of UAST nodes decoded per request; requests that return the response undecoded record 0.",
+			Buckets:   prometheus.ExponentialBuckets(8, 4, 8),
+		}, []string{"method", "language"}),
+		ResponseBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "bblfsh_client",
+			Name:      "response_bytes",
+			Help:      "Size, in bytes, of the content parsed per request.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		}, []string{"method", "language"}),
+		Requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "bblfsh_client",
+			Name:      "requests_total",
+			Help:      "Number of bblfsh client requests by status.",
+		}, []string{"method", "language", "status"}),
+	}
+	reg.MustRegister(m.Latency, m.ResponseNodes, m.ResponseBytes, m.Requests)
+	return m
+}
+
+// Outcome carries what an instrumented call learned about its response,
+// once it has one: the language bblfshd detected (falling back to the
+// language it was asked for when that's all there is), the UAST node
+// count, and the size of the content that was parsed.
+type Outcome struct {
+	Language  string
+	NodeCount int
+	Bytes     int
+}
+
+// Observe records the outcome of a single request. It is a no-op on a nil
+// *Metrics, so callers don't need to guard every call site.
+func (m *Metrics) Observe(method string, elapsed time.Duration, out Outcome, err error) {
+	if m == nil {
+		return
+	}
+	m.Latency.WithLabelValues(method, out.Language).Observe(elapsed.Seconds())
+	m.ResponseNodes.WithLabelValues(method, out.Language).Observe(float64(out.NodeCount))
+	m.ResponseBytes.WithLabelValues(method, out.Language).Observe(float64(out.Bytes))
+	m.Requests.WithLabelValues(method, out.Language, statusOf(err)).Inc()
+}
+
+func statusOf(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return "error"
+}
+
+// StartSpan starts a span named method using tracer, tagged with the
+// request's language, filename and content size. If tracer is nil, it
+// returns a no-op span so callers don't need to guard every call site.
+func StartSpan(ctx context.Context, tracer opentracing.Tracer, method, language, filename string, contentBytes int) (opentracing.Span, context.Context) {
+	if tracer == nil {
+		return opentracing.NoopTracer{}.StartSpan(method), ctx
+	}
+	span, ctx := opentracing.StartSpanFromContextWithTracer(ctx, tracer, method)
+	span.SetTag("language", language)
+	span.SetTag("filename", filename)
+	span.SetTag("content.bytes", contentBytes)
+	return span, ctx
+}
@@ -0,0 +1,84 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegisterRegistersCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := Register(reg)
+
+	m.Observe("Parse", time.Second, Outcome{Language: "Go", NodeCount: 3, Bytes: 10}, nil)
+
+	if n := testutil.CollectAndCount(m.Latency); n != 1 {
+		t.Errorf("Latency series = %d, want 1", n)
+	}
+	if n := testutil.CollectAndCount(m.ResponseNodes); n != 1 {
+		t.Errorf("ResponseNodes series = %d, want 1", n)
+	}
+	if n := testutil.CollectAndCount(m.ResponseBytes); n != 1 {
+		t.Errorf("ResponseBytes series = %d, want 1", n)
+	}
+	if n := testutil.CollectAndCount(m.Requests); n != 1 {
+		t.Errorf("Requests series = %d, want 1", n)
+	}
+}
+
+func TestObserveLabelsAndStatus(t *testing.T) {
+	m := Register(prometheus.NewRegistry())
+	m.Observe("Parse", time.Second, Outcome{Language: "Go", NodeCount: 3, Bytes: 10}, nil)
+	m.Observe("Parse", time.Second, Outcome{Language: "Go"}, errors.New("boom"))
+
+	if got := testutil.ToFloat64(m.Requests.WithLabelValues("Parse", "Go", "ok")); got != 1 {
+		t.Errorf("ok requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.Requests.WithLabelValues("Parse", "Go", "error")); got != 1 {
+		t.Errorf("error requests = %v, want 1", got)
+	}
+}
+
+func TestObserveNilMetricsIsANoop(t *testing.T) {
+	var m *Metrics
+	m.Observe("Parse", time.Second, Outcome{Language: "Go"}, nil)
+}
+
+func TestStartSpanNilTracerReturnsNoopSpan(t *testing.T) {
+	ctx := context.Background()
+	span, gotCtx := StartSpan(ctx, nil, "Parse", "Go", "main.go", 10)
+	if gotCtx != ctx {
+		t.Error("expected the context to be returned unchanged when tracer is nil")
+	}
+	span.Finish() // must not panic
+}
+
+func TestStartSpanTagsTheSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	span, _ := StartSpan(context.Background(), tracer, "Parse", "Go", "main.go", 10)
+	span.Finish()
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("FinishedSpans() = %d, want 1", len(spans))
+	}
+	got := spans[0]
+	if got.OperationName != "Parse" {
+		t.Errorf("OperationName = %q, want %q", got.OperationName, "Parse")
+	}
+	tags := got.Tags()
+	if tags["language"] != "Go" {
+		t.Errorf("language tag = %v, want %q", tags["language"], "Go")
+	}
+	if tags["filename"] != "main.go" {
+		t.Errorf("filename tag = %v, want %q", tags["filename"], "main.go")
+	}
+	if tags["content.bytes"] != 10 {
+		t.Errorf("content.bytes tag = %v, want %v", tags["content.bytes"], 10)
+	}
+}
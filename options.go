@@ -0,0 +1,15 @@
+package bblfsh
+
+import "github.com/bblfsh/client-go/cache"
+
+// Option configures a Client at construction time.
+type Option func(*Client)
+
+// WithCache makes the client consult c for a cached UAST, keyed by the
+// (language, mode, content) of the request, before sending a parse
+// request to bblfshd. The result of a cache miss is stored back into c.
+func WithCache(c cache.Cache) Option {
+	return func(cl *Client) {
+		cl.cache = c
+	}
+}
@@ -0,0 +1,56 @@
+package bblfsh
+
+import (
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// connPool round-robins requests across a fixed set of gRPC connections
+// dialed to the same endpoint, so that a single slow or reset connection
+// does not serialize every in-flight request.
+type connPool struct {
+	conns []*grpc.ClientConn
+	next  uint64
+}
+
+func newConnPool(conns []*grpc.ClientConn) *connPool {
+	return &connPool{conns: conns}
+}
+
+// Get returns the next connection in the pool.
+func (p *connPool) Get() *grpc.ClientConn {
+	if len(p.conns) == 1 {
+		return p.conns[0]
+	}
+	i := atomic.AddUint64(&p.next, 1)
+	return p.conns[i%uint64(len(p.conns))]
+}
+
+// Close closes every connection in the pool.
+func (p *connPool) Close() error {
+	var err error
+	for _, c := range p.conns {
+		if cerr := c.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// WithPoolSize dials n gRPC connections to the same endpoint and
+// round-robins requests across them, instead of sharing a single
+// connection. The default pool size is 1.
+func WithPoolSize(n int) Option {
+	return func(cl *Client) {
+		cl.poolSize = n
+	}
+}
+
+// WithDialOptions appends extra grpc.DialOptions used for every
+// connection the client dials.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(cl *Client) {
+		cl.dialOpts = append(cl.dialOpts, opts...)
+	}
+}
@@ -0,0 +1,36 @@
+package bblfsh
+
+import (
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestConnPoolGetRoundRobins(t *testing.T) {
+	conns := []*grpc.ClientConn{{}, {}, {}}
+	p := newConnPool(conns)
+
+	var seen []*grpc.ClientConn
+	for i := 0; i < len(conns)*2; i++ {
+		seen = append(seen, p.Get())
+	}
+
+	for i, c := range seen {
+		want := conns[(i+1)%len(conns)]
+		if c != want {
+			t.Fatalf("Get() call #%d returned a different connection than expected", i)
+		}
+	}
+}
+
+func TestConnPoolGetSingleConn(t *testing.T) {
+	conn := &grpc.ClientConn{}
+	p := newConnPool([]*grpc.ClientConn{conn})
+
+	if got := p.Get(); got != conn {
+		t.Fatalf("Get() = %v, want the only connection in the pool", got)
+	}
+	if got := p.Get(); got != conn {
+		t.Fatalf("Get() = %v, want the only connection in the pool", got)
+	}
+}
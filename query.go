@@ -0,0 +1,72 @@
+package bblfsh
+
+import (
+	"context"
+
+	"github.com/bblfsh/client-go/tools"
+	"gopkg.in/bblfsh/sdk.v2/uast/nodes"
+)
+
+// QueryRequest parses a file and applies an XPath query against the
+// resulting UAST, returning the matching nodes directly.
+type QueryRequest struct {
+	parse *ParseRequestV2
+	xpath string
+}
+
+// NewQueryRequest creates a request that parses a file and runs an XPath
+// query over the resulting UAST, removing the need to call UASTContext
+// and tools.Filter separately.
+func (c *Client) NewQueryRequest() *QueryRequest {
+	return &QueryRequest{parse: &ParseRequestV2{client: c}}
+}
+
+// Query sets the XPath (or semantic query dialect supported by
+// bblfsh/client-go/tools) used to filter the UAST.
+func (r *QueryRequest) Query(xpath string) *QueryRequest {
+	r.xpath = xpath
+	return r
+}
+
+// Language sets the language of the given source file to parse. If
+// missing, it will be guessed from the filename and the content.
+func (r *QueryRequest) Language(language string) *QueryRequest {
+	r.parse.Language(language)
+	return r
+}
+
+// Mode controls the level of transformation applied to the UAST before
+// the query is run against it.
+func (r *QueryRequest) Mode(mode Mode) *QueryRequest {
+	r.parse.Mode(mode)
+	return r
+}
+
+// ReadFile loads a file given a local path and sets the content and the
+// filename of the request.
+func (r *QueryRequest) ReadFile(fp string) *QueryRequest {
+	r.parse.ReadFile(fp)
+	return r
+}
+
+// Content sets the content of the parse request. It should be the source
+// code that wants to be parsed.
+func (r *QueryRequest) Content(content string) *QueryRequest {
+	r.parse.Content(content)
+	return r
+}
+
+// Do is the same as DoContext, but uses context.Background as a context.
+func (r *QueryRequest) Do() ([]nodes.Node, error) {
+	return r.DoContext(context.Background())
+}
+
+// DoContext parses the file and applies the query, returning every
+// matching node.
+func (r *QueryRequest) DoContext(ctx context.Context) ([]nodes.Node, error) {
+	ast, _, err := r.parse.UASTContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tools.Filter(ast, r.xpath)
+}
@@ -0,0 +1,25 @@
+package bblfsh
+
+import "testing"
+
+func TestQueryRequestSettersConfigureTheUnderlyingParse(t *testing.T) {
+	c := &Client{}
+	r := c.NewQueryRequest().
+		Query("//uast:Identifier").
+		Language("Go").
+		Mode(Semantic).
+		Content("package main")
+
+	if r.xpath != "//uast:Identifier" {
+		t.Errorf("xpath = %q, want %q", r.xpath, "//uast:Identifier")
+	}
+	if r.parse.internal.Language != "Go" {
+		t.Errorf("Language = %q, want %q", r.parse.internal.Language, "Go")
+	}
+	if r.parse.internal.Mode != Semantic {
+		t.Errorf("Mode = %v, want %v", r.parse.internal.Mode, Semantic)
+	}
+	if r.parse.internal.Content != "package main" {
+		t.Errorf("Content = %q, want %q", r.parse.internal.Content, "package main")
+	}
+}
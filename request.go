@@ -2,12 +2,18 @@ package bblfsh
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+
+	"github.com/bblfsh/client-go/cache"
+	"github.com/bblfsh/client-go/observability"
 	protocol1 "gopkg.in/bblfsh/sdk.v1/protocol"
 	"gopkg.in/bblfsh/sdk.v2/driver"
 	protocol2 "gopkg.in/bblfsh/sdk.v2/protocol"
@@ -33,6 +39,11 @@ type ParseRequestV2 struct {
 	internal protocol2.ParseRequest
 	client   *Client
 	err      error
+
+	encoding         protocol1.Encoding
+	charset          encoding.Encoding
+	transcoded       bool
+	detectedEncoding string
 }
 
 // Language sets the language of the given source file to parse. if missing
@@ -69,6 +80,85 @@ func (r *ParseRequestV2) Filename(filename string) *ParseRequestV2 {
 	return r
 }
 
+// Encoding sets the text encoding of Content, mirroring the knob
+// available on the v1 ParseRequest. It defaults to protocol1.UTF8, which
+// is a no-op; set it to protocol1.Base64 when Content holds base64-encoded
+// bytes rather than raw UTF-8 text.
+func (r *ParseRequestV2) Encoding(enc protocol1.Encoding) *ParseRequestV2 {
+	r.encoding = enc
+	return r
+}
+
+// Charset declares that, once any Encoding has been applied, Content is
+// not UTF-8 but some other charset (for example
+// golang.org/x/text/encoding/charmap.Windows1252,
+// .../japanese.ShiftJIS or .../simplifiedchinese.GBK). It is transcoded
+// to UTF-8 before being sent to bblfshd. UTF-16 content carrying a
+// byte-order mark is detected automatically and does not need this call.
+func (r *ParseRequestV2) Charset(charset encoding.Encoding) *ParseRequestV2 {
+	r.charset = charset
+	return r
+}
+
+// DetectedEncoding returns the charset that was applied to transcode
+// Content to UTF-8, or "utf-8" if none was needed. It is only meaningful
+// after Do/DoContext/UAST/UASTContext has been called.
+func (r *ParseRequestV2) DetectedEncoding() string {
+	return r.detectedEncoding
+}
+
+// transcodeToUTF8 decodes r.internal.Content according to r.encoding and
+// r.charset (or a sniffed UTF-16 BOM), leaving it as valid UTF-8. It is a
+// no-op once a call has succeeded; a failed call leaves Content unchanged
+// and a later call tries again.
+func (r *ParseRequestV2) transcodeToUTF8() error {
+	if r.transcoded {
+		return nil
+	}
+
+	content := r.internal.Content
+	if r.encoding == protocol1.Base64 {
+		raw, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return fmt.Errorf("cannot decode base64 content: %v", err)
+		}
+		content = string(raw)
+	}
+
+	charset := r.charset
+	if charset == nil {
+		charset = sniffUTF16(content)
+	}
+	if charset == nil {
+		r.detectedEncoding = "utf-8"
+		r.internal.Content = content
+		r.transcoded = true
+		return nil
+	}
+
+	decoded, err := charset.NewDecoder().String(content)
+	if err != nil {
+		return fmt.Errorf("cannot transcode content to utf-8: %v", err)
+	}
+	r.detectedEncoding = fmt.Sprintf("%v", charset)
+	r.internal.Content = decoded
+	r.transcoded = true
+	return nil
+}
+
+// sniffUTF16 returns a decoder for UTF-16LE or UTF-16BE if content
+// begins with the corresponding byte-order mark, or nil otherwise.
+func sniffUTF16(content string) encoding.Encoding {
+	b := []byte(content)
+	switch {
+	case len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF:
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	}
+	return nil
+}
+
 // Mode controls the level of transformation applied to UAST.
 type Mode = protocol2.Mode
 
@@ -109,8 +199,22 @@ func (r *ParseRequestV2) DoContext(ctx context.Context) (*protocol2.ParseRespons
 	if r.err != nil {
 		return nil, r.err
 	}
+	if err := r.transcodeToUTF8(); err != nil {
+		return nil, err
+	}
 
-	resp, err := r.client.service2.Parse(ctx, &r.internal)
+	var resp *protocol2.ParseResponse
+	err := r.client.instrument(ctx, "ParseRequestV2.Do", r.internal.Language, r.internal.Filename, len(r.internal.Content), func() (observability.Outcome, error) {
+		rerr := withRetry(ctx, r.client.retry, func() error {
+			var err error
+			resp, err = r.client.service2Client().Parse(ctx, &r.internal)
+			return err
+		})
+		if rerr != nil || resp == nil {
+			return observability.Outcome{}, rerr
+		}
+		return observability.Outcome{Language: resp.Language, Bytes: len(r.internal.Content)}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -129,15 +233,43 @@ func (r *ParseRequestV2) UASTContext(ctx context.Context) (nodes.Node, string, e
 	if r.err != nil {
 		return nil, "", r.err
 	}
-	resp, err := r.client.service2.Parse(ctx, &r.internal)
-	if err != nil {
+	if err := r.transcodeToUTF8(); err != nil {
 		return nil, "", err
 	}
-	ast, err := resp.Nodes()
+	var key cache.Key
+	if c := r.client.cache; c != nil {
+		key = cacheKey(r.internal.Language, r.internal.Mode, r.internal.Content)
+		if v, ok, err := c.Get(key); err == nil && ok {
+			return v.AST, v.Language, nil
+		}
+	}
+	var ast nodes.Node
+	var lang string
+	err := r.client.instrument(ctx, "ParseRequestV2.UAST", r.internal.Language, r.internal.Filename, len(r.internal.Content), func() (observability.Outcome, error) {
+		var resp *protocol2.ParseResponse
+		rerr := withRetry(ctx, r.client.retry, func() error {
+			var err error
+			resp, err = r.client.service2Client().Parse(ctx, &r.internal)
+			return err
+		})
+		if rerr != nil {
+			return observability.Outcome{}, rerr
+		}
+		lang = resp.Language
+		var err error
+		ast, err = resp.Nodes()
+		if err != nil {
+			return observability.Outcome{Language: lang}, err
+		}
+		return observability.Outcome{Language: lang, NodeCount: countNodes(ast), Bytes: len(r.internal.Content)}, nil
+	})
 	if err != nil {
-		return nil, resp.Language, err
+		return nil, lang, err
 	}
-	return ast, resp.Language, nil
+	if c := r.client.cache; c != nil {
+		_ = c.Put(key, cache.Value{Language: lang, AST: ast})
+	}
+	return ast, lang, nil
 }
 
 // ParseRequest is a parsing request to get the UAST.
@@ -210,7 +342,18 @@ func (r *ParseRequest) DoWithContext(ctx context.Context) (*protocol1.ParseRespo
 		return r.doV2(ctx)
 	}
 
-	resp, err := r.client.service1.Parse(ctx, &r.internal)
+	var resp *protocol1.ParseResponse
+	err := r.client.instrument(ctx, "ParseRequest.Do", r.internal.Language, r.internal.Filename, len(r.internal.Content), func() (observability.Outcome, error) {
+		rerr := withRetry(ctx, r.client.retry, func() error {
+			var err error
+			resp, err = r.client.service1Client().Parse(ctx, &r.internal)
+			return err
+		})
+		if rerr != nil || resp == nil {
+			return observability.Outcome{}, rerr
+		}
+		return observability.Outcome{Language: resp.Language, Bytes: len(r.internal.Content)}, nil
+	})
 	if err != nil {
 		return nil, err
 	} else if resp.Status == protocol1.Fatal {
@@ -265,15 +408,42 @@ func (r *ParseRequest) UASTContext(ctx context.Context) (nodes.Node, string, err
 	if r.mode != nil {
 		req.Mode = *r.mode
 	}
-	resp, err := r.client.service2.Parse(ctx, req)
-	if err != nil {
-		return nil, "", err
+
+	var key cache.Key
+	if c := r.client.cache; c != nil {
+		key = cacheKey(req.Language, req.Mode, req.Content)
+		if v, ok, err := c.Get(key); err == nil && ok {
+			return v.AST, v.Language, nil
+		}
 	}
-	ast, err := resp.Nodes()
+
+	var ast nodes.Node
+	var lang string
+	err := r.client.instrument(ctx, "ParseRequest.UAST", req.Language, req.Filename, len(req.Content), func() (observability.Outcome, error) {
+		var resp *protocol2.ParseResponse
+		rerr := withRetry(ctx, r.client.retry, func() error {
+			var err error
+			resp, err = r.client.service2Client().Parse(ctx, req)
+			return err
+		})
+		if rerr != nil {
+			return observability.Outcome{}, rerr
+		}
+		lang = resp.Language
+		var err error
+		ast, err = resp.Nodes()
+		if err != nil {
+			return observability.Outcome{Language: lang}, fmt.Errorf("cannot decode the uast: %v", err)
+		}
+		return observability.Outcome{Language: lang, NodeCount: countNodes(ast), Bytes: len(req.Content)}, nil
+	})
 	if err != nil {
-		return nil, resp.Language, fmt.Errorf("cannot decode the uast: %v", err)
+		return nil, lang, err
+	}
+	if c := r.client.cache; c != nil {
+		_ = c.Put(key, cache.Value{Language: lang, AST: ast})
 	}
-	return ast, resp.Language, nil
+	return ast, lang, nil
 }
 
 // NativeParseRequest is a parsing request to get the AST.
@@ -336,7 +506,18 @@ func (r *NativeParseRequest) DoWithContext(ctx context.Context) (*protocol1.Nati
 		return nil, r.err
 	}
 
-	resp, err := r.client.service1.NativeParse(ctx, &r.internal)
+	var resp *protocol1.NativeParseResponse
+	err := r.client.instrument(ctx, "NativeParseRequest.Do", r.internal.Language, r.internal.Filename, len(r.internal.Content), func() (observability.Outcome, error) {
+		rerr := withRetry(ctx, r.client.retry, func() error {
+			var err error
+			resp, err = r.client.service1Client().NativeParse(ctx, &r.internal)
+			return err
+		})
+		if rerr != nil || resp == nil {
+			return observability.Outcome{}, rerr
+		}
+		return observability.Outcome{Language: resp.Language, Bytes: len(r.internal.Content)}, nil
+	})
 	if err != nil {
 		return nil, err
 	} else if resp.Status == protocol1.Fatal {
@@ -364,7 +545,14 @@ func (r *VersionRequest) DoWithContext(ctx context.Context) (*protocol1.VersionR
 		return nil, r.err
 	}
 
-	resp, err := r.client.service1.Version(ctx, &protocol1.VersionRequest{})
+	var resp *protocol1.VersionResponse
+	err := r.client.instrument(ctx, "VersionRequest.Do", "", "", 0, func() (observability.Outcome, error) {
+		return observability.Outcome{}, withRetry(ctx, r.client.retry, func() error {
+			var err error
+			resp, err = r.client.service1Client().Version(ctx, &protocol1.VersionRequest{})
+			return err
+		})
+	})
 	if err != nil {
 		return nil, err
 	} else if resp.Status == protocol1.Fatal {
@@ -392,7 +580,14 @@ func (r *SupportedLanguagesRequest) DoWithContext(ctx context.Context) (*protoco
 		return nil, r.err
 	}
 
-	resp, err := r.client.service1.SupportedLanguages(ctx, &protocol1.SupportedLanguagesRequest{})
+	var resp *protocol1.SupportedLanguagesResponse
+	err := r.client.instrument(ctx, "SupportedLanguagesRequest.Do", "", "", 0, func() (observability.Outcome, error) {
+		return observability.Outcome{}, withRetry(ctx, r.client.retry, func() error {
+			var err error
+			resp, err = r.client.service1Client().SupportedLanguages(ctx, &protocol1.SupportedLanguagesRequest{})
+			return err
+		})
+	})
 	if err != nil {
 		return nil, err
 	} else if resp.Status == protocol1.Fatal {
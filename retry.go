@@ -0,0 +1,78 @@
+package bblfsh
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy controls how transient gRPC errors are retried by Client.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of attempts after the first one.
+	MaxRetries int
+	// MinBackoff and MaxBackoff bound the exponential backoff applied
+	// between retries. Each wait is drawn at random from [0, backoff], so
+	// that many clients retrying at once don't stay in lockstep.
+	MinBackoff, MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is a conservative policy suitable for clients
+// talking to a bblfshd cluster behind a load balancer.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 100 * time.Millisecond,
+	MaxBackoff: 2 * time.Second,
+}
+
+// WithRetry enables automatic retries, with exponential backoff, on
+// transient gRPC errors (Unavailable, DeadlineExceeded,
+// ResourceExhausted).
+func WithRetry(policy RetryPolicy) Option {
+	return func(cl *Client) {
+		cl.retry = &policy
+	}
+}
+
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// backoff returns the wait duration for the given 0-based retry attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff << uint(attempt)
+	if d > p.MaxBackoff || d <= 0 {
+		d = p.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// withRetry calls fn, retrying it according to policy as long as it
+// returns a retryable error and ctx allows it. A nil policy disables
+// retries entirely.
+func withRetry(ctx context.Context, policy *RetryPolicy, fn func() error) error {
+	if policy == nil {
+		return fn()
+	}
+	var err error
+	for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
+		if err = fn(); err == nil || !isRetryable(err) {
+			return err
+		}
+		if attempt == policy.MaxRetries {
+			break
+		}
+		select {
+		case <-time.After(policy.backoff(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
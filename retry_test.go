@@ -0,0 +1,86 @@
+package bblfsh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("boom"), false},
+		{status.Error(codes.Unavailable, "down"), true},
+		{status.Error(codes.DeadlineExceeded, "timeout"), true},
+		{status.Error(codes.ResourceExhausted, "busy"), true},
+		{status.Error(codes.InvalidArgument, "bad request"), false},
+	}
+	for _, c := range cases {
+		if got := isRetryable(c.err); got != c.want {
+			t.Errorf("isRetryable(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffIsBounded(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	for attempt := 0; attempt < 10; attempt++ {
+		d := p.backoff(attempt)
+		if d < 0 || d > p.MaxBackoff {
+			t.Fatalf("backoff(%d) = %v, want within [0, %v]", attempt, d, p.MaxBackoff)
+		}
+	}
+}
+
+func TestWithRetryStopsOnNonRetryableError(t *testing.T) {
+	calls := 0
+	err := withRetry(context.Background(), &DefaultRetryPolicy, func() error {
+		calls++
+		return errors.New("permanent failure")
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1 (no retries for a non-retryable error)", calls)
+	}
+}
+
+func TestWithRetryRetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	err := withRetry(context.Background(), &policy, func() error {
+		calls++
+		if calls < 3 {
+			return status.Error(codes.Unavailable, "down")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestWithRetryNilPolicyDisablesRetries(t *testing.T) {
+	calls := 0
+	err := withRetry(context.Background(), nil, func() error {
+		calls++
+		return status.Error(codes.Unavailable, "down")
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}